@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/tls"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/sujit-baniya/frame/pkg/common/config"
+	"github.com/sujit-baniya/frame/pkg/network/standard"
+)
+
+// H2Config tunes HTTP/2 connection liveness checking, so half-open
+// connections behind a stalled load balancer are detected and evicted instead
+// of stalling requests indefinitely.
+type H2Config struct {
+	// ReadIdleTimeout is how long an h2 connection may sit idle before a
+	// health-check ping is sent. Zero disables liveness pings.
+	ReadIdleTimeout time.Duration
+	// PingTimeout is how long to wait for a ping ack before the connection is
+	// considered dead and evicted.
+	PingTimeout time.Duration
+	// WriteByteTimeout is the maximum time allowed to write a single byte of
+	// a request or ping frame before the connection is closed.
+	WriteByteTimeout time.Duration
+	// MaxReadFrameSize caps the size of frames read from the peer. Zero uses
+	// the http2 package's default.
+	MaxReadFrameSize uint32
+}
+
+// WithH2Transport builds an *http2.Transport from cfg and installs it as
+// o.H2Transport, the RoundTripper the client's TLS transport uses once a
+// connection negotiates h2. With ReadIdleTimeout and PingTimeout set,
+// http2.Transport itself pings idle connections and closes the ones that
+// don't ack in time, so WithRetryConfig transparently recovers the request on
+// a fresh connection.
+//
+// It only falls back to standard.NewDialer() when no dialer was configured
+// yet, so it composes with an explicit WithDialer instead of clobbering it.
+func WithH2Transport(cfg H2Config) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		if o.Dialer == nil {
+			o.Dialer = standard.NewDialer()
+		}
+
+		if o.TLSConfig == nil {
+			o.TLSConfig = &tls.Config{}
+		}
+		if len(o.TLSConfig.NextProtos) == 0 {
+			o.TLSConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+
+		o.H2Transport = &http2.Transport{
+			ReadIdleTimeout:  cfg.ReadIdleTimeout,
+			PingTimeout:      cfg.PingTimeout,
+			WriteByteTimeout: cfg.WriteByteTimeout,
+			MaxReadFrameSize: cfg.MaxReadFrameSize,
+		}
+	}}
+}
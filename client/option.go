@@ -19,8 +19,11 @@ package client
 import (
 	"crypto/tls"
 	"github.com/sujit-baniya/frame/client/retry"
+	"github.com/sujit-baniya/frame/middlewares/server/spiffe"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/sujit-baniya/frame/pkg/common/config"
 	"github.com/sujit-baniya/frame/pkg/network"
 	"github.com/sujit-baniya/frame/pkg/network/standard"
@@ -84,6 +87,21 @@ func WithTLSConfig(cfg *tls.Config) config.ClientOption {
 	}}
 }
 
+// WithSPIFFE sets the TLS config from a SPIFFE Workload API X.509 source, so
+// outbound connections authenticate with the workload's rotating SVID and
+// verify the peer against authorizer, e.g. tlsconfig.AuthorizeMemberOf(trustDomain).
+//
+// It only falls back to standard.NewDialer() when no dialer was configured
+// yet, so it composes with an explicit WithDialer instead of clobbering it.
+func WithSPIFFE(source *workloadapi.X509Source, authorizer tlsconfig.Authorizer) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.TLSConfig = spiffe.ClientTLSConfig(source, authorizer)
+		if o.Dialer == nil {
+			o.Dialer = standard.NewDialer()
+		}
+	}}
+}
+
 // WithDialer sets the specific dialer.
 func WithDialer(d network.Dialer) config.ClientOption {
 	return config.ClientOption{F: func(o *config.ClientOptions) {
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package frame is the server entry point: it owns the live route.Engine and
+// is the receiver reload.go's Reload swaps the engine on.
+package frame
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sujit-baniya/frame/pkg/common/config"
+	"github.com/sujit-baniya/frame/pkg/route"
+)
+
+// Context and HandlerFunc are the route package's request context and
+// handler signature, aliased here so callers write frame.Context and
+// frame.HandlerFunc instead of reaching into pkg/route directly.
+type (
+	Context     = route.Context
+	HandlerFunc = route.HandlerFunc
+)
+
+// Frame wraps a route.Engine, adding the indirection Reload needs to swap the
+// engine under an already-open listener: every request is dispatched through
+// f.ServeHTTP (see reload.go), which always reads the current f.engine, not
+// whichever engine Spin captured at startup.
+type Frame struct {
+	mu     sync.RWMutex
+	engine *route.Engine
+}
+
+// New builds a Frame from opts.
+func New(opts ...config.Option) *Frame {
+	return &Frame{engine: route.NewEngine(config.NewOptions(opts))}
+}
+
+// Default is an alias for New kept for symmetry with the option constructors;
+// this slice of the repo has no default middleware to install.
+func Default(opts ...config.Option) *Frame {
+	return New(opts...)
+}
+
+// Spin starts serving on the transport built from f's options and blocks
+// until the engine stops. Handing f.ServeHTTP to the engine, rather than
+// letting the engine serve itself directly, is what makes a later Reload
+// observable on the listener Spin opened: the engine calls back into
+// f.ServeHTTP per request, and f.ServeHTTP always resolves the current
+// f.engine under f.mu, so a swap takes effect for the next request without
+// requiring a new listener.
+//
+// If the options carry an OnReload hook (installed by WithReloadOnSignal),
+// Spin invokes it before serving and runs its cleanup after the engine
+// returns, so the signal handler is only ever registered for the lifetime of
+// a running Frame.
+func (f *Frame) Spin() {
+	opts := f.currentEngine().Options()
+	if opts.OnReload != nil {
+		cleanup := opts.OnReload(f)
+		defer cleanup()
+	}
+	f.currentEngine().Run(f.ServeHTTP)
+}
+
+// Shutdown gracefully stops f, closing the current engine and then running
+// every hook registered via o.ShutdownHooks (e.g. the observability.Manager
+// installed by server.WithObservability), returning the first error
+// encountered.
+func (f *Frame) Shutdown(ctx context.Context) error {
+	engine := f.currentEngine()
+
+	var firstErr error
+	if err := engine.Close(); err != nil {
+		firstErr = err
+	}
+	for _, hook := range engine.Options().ShutdownHooks {
+		if err := hook(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,373 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package autotls
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/providers/dns/cloudflare"
+	"github.com/go-acme/lego/v4/providers/dns/rfc2136"
+	"github.com/go-acme/lego/v4/providers/dns/route53"
+	"github.com/go-acme/lego/v4/registration"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// renewBefore is how far ahead of a cached certificate's expiry
+// NewTlsConfigWithChallenge re-obtains it instead of reusing the cached copy.
+const renewBefore = 30 * 24 * time.Hour
+
+// ChallengeType selects which ACME challenge autotls satisfies to prove
+// domain ownership.
+type ChallengeType int
+
+const (
+	// ChallengeHTTP01 satisfies the challenge the same way NewTlsConfig does,
+	// via autocert.Manager. It cannot issue wildcard certificates.
+	ChallengeHTTP01 ChallengeType = iota
+	// ChallengeDNS01 satisfies the challenge through DNSProvider via
+	// go-acme/lego, and can issue wildcard certificates.
+	ChallengeDNS01
+)
+
+// DNSProvider fulfills a DNS-01 challenge by publishing and later removing the
+// TXT record derived from token and keyAuth for domain.
+type DNSProvider interface {
+	Present(domain, token, keyAuth string) error
+	CleanUp(domain, token, keyAuth string) error
+}
+
+// ChallengeConfig selects how autotls proves domain ownership to the ACME CA.
+type ChallengeConfig struct {
+	// Type selects the challenge. Defaults to ChallengeHTTP01.
+	Type ChallengeType
+	// DNSProvider is required when Type is ChallengeDNS01.
+	DNSProvider DNSProvider
+	// Email is the ACME account contact used when registering with the CA.
+	// Required when Type is ChallengeDNS01.
+	Email string
+	// CADirURL overrides the default Let's Encrypt production directory, e.g.
+	// to point at the staging directory or a private ACME server.
+	CADirURL string
+	// Cache stores issued certificates and, for DNS-01, the ACME account
+	// between restarts, so a Redis- or S3-backed autocert.Cache can be
+	// plugged in via WithCertCache. Without a Cache, HTTP-01 falls back to
+	// NewTlsConfig and its on-disk cache, while DNS-01 registers a new ACME
+	// account and obtains a fresh certificate on every call, which risks the
+	// CA's rate limits.
+	Cache autocert.Cache
+}
+
+// Option customizes a ChallengeConfig.
+type Option func(*ChallengeConfig)
+
+// WithCertCache sets the cache used to persist issued certificates and, for
+// DNS-01, the ACME account between restarts.
+func WithCertCache(cache autocert.Cache) Option {
+	return func(c *ChallengeConfig) {
+		c.Cache = cache
+	}
+}
+
+// Apply returns a copy of cfg with opts applied, e.g.
+//
+//	cfg := autotls.ChallengeConfig{Type: autotls.ChallengeDNS01, DNSProvider: p}.
+//		Apply(autotls.WithCertCache(cache))
+func (cfg ChallengeConfig) Apply(opts ...Option) ChallengeConfig {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WildcardHostPolicy returns an autocert.HostPolicy that also accepts hosts
+// covered by a wildcard entry in domains (e.g. "*.example.com" allows
+// "api.example.com"), unlike autocert.HostWhitelist which only matches exact
+// names. It is only useful for manually-built autocert.Manager values, since
+// wildcard certificates themselves must be issued via DNS-01.
+func WildcardHostPolicy(domains ...string) autocert.HostPolicy {
+	wildcards := make([]string, 0, len(domains))
+	exact := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if strings.HasPrefix(d, "*.") {
+			wildcards = append(wildcards, d[1:]) // keep the leading dot
+			continue
+		}
+		exact[d] = true
+	}
+
+	return func(_ context.Context, host string) error {
+		if exact[host] {
+			return nil
+		}
+		for _, suffix := range wildcards {
+			if strings.HasSuffix(host, suffix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("autotls: host %q is not covered by the configured domains", host)
+	}
+}
+
+// NewTlsConfigWithChallenge issues a certificate for domains using the
+// challenge described by cfg. HTTP-01 builds an autocert.Manager (honoring
+// cfg.Cache if set, same as NewTlsConfig); DNS-01 drives go-acme/lego with
+// cfg.DNSProvider and can issue wildcard certificates. When cfg.Cache is set,
+// the DNS-01 path reuses a previously persisted ACME account instead of
+// re-registering, and reuses a cached certificate until it's within
+// renewBefore of expiring.
+func NewTlsConfigWithChallenge(cfg ChallengeConfig, domains ...string) (*tls.Config, error) {
+	ctx := context.Background()
+
+	if cfg.Type == ChallengeHTTP01 {
+		if cfg.Cache == nil {
+			return NewTlsConfig(domains...), nil
+		}
+		m := &autocert.Manager{Prompt: autocert.AcceptTOS, Cache: cfg.Cache}
+		if len(domains) > 0 {
+			m.HostPolicy = autocert.HostWhitelist(domains...)
+		}
+		return m.TLSConfig(), nil
+	}
+
+	if cfg.DNSProvider == nil {
+		return nil, fmt.Errorf("autotls: DNS-01 challenge requires a DNSProvider")
+	}
+
+	if cfg.Cache != nil {
+		if cert, err := loadCachedCert(ctx, cfg.Cache, domains); err == nil {
+			return &tls.Config{Certificates: []tls.Certificate{*cert}}, nil
+		}
+	}
+
+	user, err := loadOrCreateACMEUser(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	legoCfg := lego.NewConfig(user)
+	if cfg.CADirURL != "" {
+		legoCfg.CADirURL = cfg.CADirURL
+	}
+
+	client, err := lego.NewClient(legoCfg)
+	if err != nil {
+		return nil, fmt.Errorf("autotls: creating acme client: %w", err)
+	}
+	if err := client.Challenge.SetDNS01Provider(cfg.DNSProvider); err != nil {
+		return nil, fmt.Errorf("autotls: registering dns-01 provider: %w", err)
+	}
+
+	if user.registration == nil {
+		reg, err := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		if err != nil {
+			return nil, fmt.Errorf("autotls: registering acme account: %w", err)
+		}
+		user.registration = reg
+	}
+
+	if err := saveACMEUser(ctx, cfg.Cache, user); err != nil {
+		return nil, err
+	}
+
+	certs, err := client.Certificate.Obtain(certificate.ObtainRequest{
+		Domains: domains,
+		Bundle:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("autotls: obtaining certificate: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair(certs.Certificate, certs.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("autotls: parsing issued certificate: %w", err)
+	}
+
+	if err := saveCachedCert(ctx, cfg.Cache, domains, certs.Certificate, certs.PrivateKey); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// NewCloudflareDNSProvider returns a DNSProvider backed by Cloudflare's DNS
+// API.
+func NewCloudflareDNSProvider(cfg *cloudflare.Config) (DNSProvider, error) {
+	return cloudflare.NewDNSProviderConfig(cfg)
+}
+
+// NewRoute53DNSProvider returns a DNSProvider backed by AWS Route53.
+func NewRoute53DNSProvider(cfg *route53.Config) (DNSProvider, error) {
+	return route53.NewDNSProviderConfig(cfg)
+}
+
+// NewRFC2136DNSProvider returns a DNSProvider backed by an RFC2136-compliant
+// DNS server (e.g. BIND with nsupdate).
+func NewRFC2136DNSProvider(cfg *rfc2136.Config) (DNSProvider, error) {
+	return rfc2136.NewDNSProviderConfig(cfg)
+}
+
+// acmeUser implements go-acme/lego's registration.User for the DNS-01 flow.
+type acmeUser struct {
+	email        string
+	registration *registration.Resource
+	key          crypto.PrivateKey
+}
+
+func newACMEUser(email string) (*acmeUser, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("autotls: generating acme account key: %w", err)
+	}
+	return &acmeUser{email: email, key: key}, nil
+}
+
+func (u *acmeUser) GetEmail() string                        { return u.email }
+func (u *acmeUser) GetRegistration() *registration.Resource { return u.registration }
+func (u *acmeUser) GetPrivateKey() crypto.PrivateKey        { return u.key }
+
+// acmeAccountCacheKey is the autocert.Cache key the DNS-01 ACME account is
+// persisted under, same convention as autocert.Manager's own cache keys.
+const acmeAccountCacheKey = "acme_account+dns01"
+
+// storedACMEAccount is the JSON-serializable form of acmeUser persisted to an
+// autocert.Cache.
+type storedACMEAccount struct {
+	Email        string                 `json:"email"`
+	Key          []byte                 `json:"key"` // PKCS#1 DER
+	Registration *registration.Resource `json:"registration"`
+}
+
+// loadOrCreateACMEUser returns the ACME account persisted in cfg.Cache, or
+// registers a new one (without persisting it yet) if there is none cached.
+func loadOrCreateACMEUser(ctx context.Context, cfg ChallengeConfig) (*acmeUser, error) {
+	if cfg.Cache != nil {
+		data, err := cfg.Cache.Get(ctx, acmeAccountCacheKey)
+		if err == nil {
+			var stored storedACMEAccount
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return nil, fmt.Errorf("autotls: decoding cached acme account: %w", err)
+			}
+			key, err := x509.ParsePKCS1PrivateKey(stored.Key)
+			if err != nil {
+				return nil, fmt.Errorf("autotls: parsing cached acme account key: %w", err)
+			}
+			return &acmeUser{email: stored.Email, key: key, registration: stored.Registration}, nil
+		}
+		if !errors.Is(err, autocert.ErrCacheMiss) {
+			return nil, fmt.Errorf("autotls: reading cached acme account: %w", err)
+		}
+	}
+
+	return newACMEUser(cfg.Email)
+}
+
+// saveACMEUser persists user to cache so the next call reuses the same
+// account instead of registering a new one with the CA.
+func saveACMEUser(ctx context.Context, cache autocert.Cache, user *acmeUser) error {
+	if cache == nil {
+		return nil
+	}
+
+	rsaKey, ok := user.key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("autotls: unsupported acme account key type %T", user.key)
+	}
+
+	data, err := json.Marshal(storedACMEAccount{
+		Email:        user.email,
+		Key:          x509.MarshalPKCS1PrivateKey(rsaKey),
+		Registration: user.registration,
+	})
+	if err != nil {
+		return fmt.Errorf("autotls: encoding acme account: %w", err)
+	}
+
+	if err := cache.Put(ctx, acmeAccountCacheKey, data); err != nil {
+		return fmt.Errorf("autotls: persisting acme account: %w", err)
+	}
+	return nil
+}
+
+// storedCert is the JSON-serializable form of an issued certificate persisted
+// to an autocert.Cache.
+type storedCert struct {
+	Cert []byte `json:"cert"` // PEM certificate chain
+	Key  []byte `json:"key"`  // PEM private key
+}
+
+func certCacheKey(domains []string) string {
+	return "cert+dns01+" + strings.Join(domains, ",")
+}
+
+// loadCachedCert returns the certificate cached for domains, as long as it
+// isn't within renewBefore of expiring.
+func loadCachedCert(ctx context.Context, cache autocert.Cache, domains []string) (*tls.Certificate, error) {
+	data, err := cache.Get(ctx, certCacheKey(domains))
+	if err != nil {
+		return nil, err
+	}
+
+	var stored storedCert
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("autotls: decoding cached certificate: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair(stored.Cert, stored.Key)
+	if err != nil {
+		return nil, fmt.Errorf("autotls: parsing cached certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("autotls: parsing cached certificate leaf: %w", err)
+	}
+	if time.Now().After(leaf.NotAfter.Add(-renewBefore)) {
+		return nil, fmt.Errorf("autotls: cached certificate for %v is due for renewal", domains)
+	}
+
+	return &cert, nil
+}
+
+// saveCachedCert persists an issued certificate so later calls reuse it
+// instead of requesting a fresh one from the CA.
+func saveCachedCert(ctx context.Context, cache autocert.Cache, domains []string, certPEM, keyPEM []byte) error {
+	if cache == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(storedCert{Cert: certPEM, Key: keyPEM})
+	if err != nil {
+		return fmt.Errorf("autotls: encoding certificate: %w", err)
+	}
+
+	if err := cache.Put(ctx, certCacheKey(domains), data); err != nil {
+		return fmt.Errorf("autotls: persisting certificate: %w", err)
+	}
+	return nil
+}
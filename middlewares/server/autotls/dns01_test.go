@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package autotls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestWildcardHostPolicy(t *testing.T) {
+	policy := WildcardHostPolicy("example.com", "*.api.example.com")
+
+	cases := []struct {
+		host    string
+		wantErr bool
+	}{
+		{"example.com", false},
+		{"foo.api.example.com", false},
+		{"bar.api.example.com", false},
+		{"other.example.com", true},
+		{"example.net", true},
+	}
+
+	for _, c := range cases {
+		err := policy(context.Background(), c.host)
+		if c.wantErr && err == nil {
+			t.Errorf("host %q: expected an error, got none", c.host)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("host %q: unexpected error: %v", c.host, err)
+		}
+	}
+}
+
+// memCache is a minimal in-memory autocert.Cache for tests.
+type memCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemCache() *memCache {
+	return &memCache{data: make(map[string][]byte)}
+}
+
+func (c *memCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.data[key]
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, nil
+}
+
+func (c *memCache) Put(_ context.Context, key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = data
+	return nil
+}
+
+func (c *memCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func TestACMEAccountCacheRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemCache()
+
+	user, err := newACMEUser("ops@example.com")
+	if err != nil {
+		t.Fatalf("newACMEUser: %v", err)
+	}
+	if err := saveACMEUser(ctx, cache, user); err != nil {
+		t.Fatalf("saveACMEUser: %v", err)
+	}
+
+	loaded, err := loadOrCreateACMEUser(ctx, ChallengeConfig{Cache: cache})
+	if err != nil {
+		t.Fatalf("loadOrCreateACMEUser: %v", err)
+	}
+	if loaded.GetEmail() != user.GetEmail() {
+		t.Fatalf("expected the cached account's email to round-trip, got %q want %q", loaded.GetEmail(), user.GetEmail())
+	}
+}
+
+func TestLoadOrCreateACMEUserWithoutCacheCreatesNew(t *testing.T) {
+	user, err := loadOrCreateACMEUser(context.Background(), ChallengeConfig{Email: "ops@example.com"})
+	if err != nil {
+		t.Fatalf("loadOrCreateACMEUser: %v", err)
+	}
+	if user.GetEmail() != "ops@example.com" {
+		t.Fatalf("expected a freshly created account with the configured email")
+	}
+}
+
+func selfSignedCert(t *testing.T, notAfter time.Time) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM
+}
+
+func TestCachedCertRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemCache()
+	domains := []string{"example.com"}
+
+	certPEM, keyPEM := selfSignedCert(t, time.Now().Add(90*24*time.Hour))
+	if err := saveCachedCert(ctx, cache, domains, certPEM, keyPEM); err != nil {
+		t.Fatalf("saveCachedCert: %v", err)
+	}
+
+	if _, err := loadCachedCert(ctx, cache, domains); err != nil {
+		t.Fatalf("loadCachedCert: %v", err)
+	}
+}
+
+func TestCachedCertNearExpiryIsRejected(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemCache()
+	domains := []string{"example.com"}
+
+	certPEM, keyPEM := selfSignedCert(t, time.Now().Add(time.Hour)) // well within renewBefore
+	if err := saveCachedCert(ctx, cache, domains, certPEM, keyPEM); err != nil {
+		t.Fatalf("saveCachedCert: %v", err)
+	}
+
+	if _, err := loadCachedCert(ctx, cache, domains); err == nil {
+		t.Fatalf("expected a near-expiry cached certificate to be rejected")
+	}
+}
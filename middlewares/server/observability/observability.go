@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package observability bundles a server's metrics registry, access-log
+// handler, and tracer behind a single Manager, so they start, participate in
+// route registration, and shut down together instead of being wired up
+// piecemeal.
+package observability
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sujit-baniya/frame/pkg/common/tracer"
+)
+
+// Registry is a single metrics backend (Prometheus, OTLP, StatsD, ...) that
+// can be combined with others behind a MultiRegistry.
+type Registry interface {
+	// ObserveRequest records one completed request's outcome.
+	ObserveRequest(ctx context.Context, method, path string, statusCode int, duration time.Duration)
+	// Shutdown flushes and releases any resources held by the registry.
+	Shutdown(ctx context.Context) error
+}
+
+// MultiRegistry fans metrics recording out to every attached Registry, so
+// e.g. Prometheus and OTLP backends can both be attached without the rest of
+// the server knowing how many there are.
+type MultiRegistry struct {
+	mu         sync.RWMutex
+	registries []Registry
+}
+
+// NewMultiRegistry returns a MultiRegistry that fans out to registries.
+func NewMultiRegistry(registries ...Registry) *MultiRegistry {
+	return &MultiRegistry{registries: registries}
+}
+
+// Add attaches an additional Registry, e.g. so an OTLP registry can be joined
+// by a Prometheus one without rebuilding the manager.
+func (m *MultiRegistry) Add(r Registry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registries = append(m.registries, r)
+}
+
+// ObserveRequest records the completed request against every attached
+// Registry.
+func (m *MultiRegistry) ObserveRequest(ctx context.Context, method, path string, statusCode int, duration time.Duration) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, r := range m.registries {
+		r.ObserveRequest(ctx, method, path, statusCode, duration)
+	}
+}
+
+// Shutdown shuts every attached registry down, returning the first error
+// encountered while still shutting down the rest.
+func (m *MultiRegistry) Shutdown(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var firstErr error
+	for _, r := range m.registries {
+		if err := r.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Participation reports whether a router participates in access logging
+// and/or tracing.
+type Participation struct {
+	AccessLog bool
+	Tracing   bool
+}
+
+// ParticipationFunc decides participation for a router, keyed by its
+// registered name, e.g. to exclude a health-check endpoint from tracing.
+type ParticipationFunc func(routerName string) Participation
+
+// Manager bundles a metrics registry, access-log handler, and tracer(s)
+// behind a single server option, and coordinates their shutdown during
+// frame.Shutdown.
+type Manager struct {
+	Metrics   *MultiRegistry
+	AccessLog io.Writer
+	Tracers   []tracer.Tracer
+
+	participation ParticipationFunc
+	tracerCloser  func(ctx context.Context) error
+	accessLogMu   sync.Mutex
+}
+
+// NewManager builds a Manager. participation may be nil, in which case every
+// router participates in both access logging and tracing.
+func NewManager(metrics *MultiRegistry, accessLog io.Writer, participation ParticipationFunc) *Manager {
+	return &Manager{Metrics: metrics, AccessLog: accessLog, participation: participation}
+}
+
+// AddTracer attaches t to the manager. closer, if non-nil, is invoked during
+// Shutdown alongside every other attached tracer's closer.
+func (m *Manager) AddTracer(t tracer.Tracer, closer func(ctx context.Context) error) {
+	m.Tracers = append(m.Tracers, t)
+	prev := m.tracerCloser
+	m.tracerCloser = func(ctx context.Context) error {
+		var err error
+		if prev != nil {
+			err = prev(ctx)
+		}
+		if closer != nil {
+			if cerr := closer(ctx); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+}
+
+// LogAccess writes line to AccessLog, serialized so concurrent requests
+// can't interleave their log lines. It is a no-op if AccessLog is nil.
+func (m *Manager) LogAccess(line string) {
+	if m.AccessLog == nil {
+		return
+	}
+	m.accessLogMu.Lock()
+	defer m.accessLogMu.Unlock()
+	io.WriteString(m.AccessLog, line)
+}
+
+// Participates reports whether routerName participates in access logging
+// and/or tracing, evaluated once per router at registration time.
+func (m *Manager) Participates(routerName string) Participation {
+	if m.participation == nil {
+		return Participation{AccessLog: true, Tracing: true}
+	}
+	return m.participation(routerName)
+}
+
+// Shutdown shuts the metrics registry down, closes every tracer, and closes
+// the access log if it implements io.Closer, returning the first error
+// encountered.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	var firstErr error
+	if m.Metrics != nil {
+		if err := m.Metrics.Shutdown(ctx); err != nil {
+			firstErr = err
+		}
+	}
+	if m.tracerCloser != nil {
+		if err := m.tracerCloser(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if closer, ok := m.AccessLog.(io.Closer); ok {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeRegistry struct {
+	observed       int
+	shutdownErr    error
+	shutdownCalled bool
+}
+
+func (f *fakeRegistry) ObserveRequest(_ context.Context, _, _ string, _ int, _ time.Duration) {
+	f.observed++
+}
+
+func (f *fakeRegistry) Shutdown(_ context.Context) error {
+	f.shutdownCalled = true
+	return f.shutdownErr
+}
+
+func TestMultiRegistryFanOut(t *testing.T) {
+	a := &fakeRegistry{}
+	b := &fakeRegistry{}
+	mr := NewMultiRegistry(a, b)
+
+	mr.ObserveRequest(context.Background(), "GET", "/", 200, time.Millisecond)
+
+	if a.observed != 1 || b.observed != 1 {
+		t.Fatalf("expected both registries to observe the request, got a=%d b=%d", a.observed, b.observed)
+	}
+}
+
+func TestMultiRegistryShutdownAggregatesFirstErrorButShutsDownAll(t *testing.T) {
+	errA := errors.New("registry a failed")
+	a := &fakeRegistry{shutdownErr: errA}
+	b := &fakeRegistry{}
+	mr := NewMultiRegistry(a, b)
+
+	err := mr.Shutdown(context.Background())
+	if !errors.Is(err, errA) {
+		t.Fatalf("expected the first registry's error to be returned, got %v", err)
+	}
+	if !b.shutdownCalled {
+		t.Fatalf("expected every registry to be shut down even after an earlier error")
+	}
+}
+
+func TestManagerParticipatesDefaultsToTrue(t *testing.T) {
+	mgr := NewManager(nil, nil, nil)
+	p := mgr.Participates("/anything")
+	if !p.AccessLog || !p.Tracing {
+		t.Fatalf("expected default participation to include access logging and tracing")
+	}
+}
+
+func TestManagerParticipatesUsesProvidedFunc(t *testing.T) {
+	mgr := NewManager(nil, nil, func(routerName string) Participation {
+		return Participation{AccessLog: routerName != "/healthz"}
+	})
+	if mgr.Participates("/healthz").AccessLog {
+		t.Fatalf("expected /healthz to opt out of access logging")
+	}
+	if !mgr.Participates("/users").AccessLog {
+		t.Fatalf("expected other routes to keep the default")
+	}
+}
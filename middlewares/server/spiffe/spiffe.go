@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package spiffe wires a SPIFFE Workload API X.509 source into the *tls.Config
+// used by the server and client TLS options, so certificates are rotated
+// automatically by SPIRE and peer SPIFFE IDs can be asserted against a trust
+// domain or allow-list.
+package spiffe
+
+import (
+	"crypto/tls"
+
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// ServerTLSConfig returns a *tls.Config suitable for a TLS server, whose
+// GetCertificate callback always returns the current SVID from source and
+// whose VerifyPeerCertificate callback authorizes client SVIDs with authorizer.
+func ServerTLSConfig(source *workloadapi.X509Source, authorizer tlsconfig.Authorizer) *tls.Config {
+	return tlsconfig.MTLSServerConfig(source, source, authorizer)
+}
+
+// ClientTLSConfig returns a *tls.Config suitable for a TLS client, whose
+// GetClientCertificate callback always returns the current SVID from source and
+// whose VerifyPeerCertificate callback authorizes server SVIDs with authorizer.
+func ClientTLSConfig(source *workloadapi.X509Source, authorizer tlsconfig.Authorizer) *tls.Config {
+	return tlsconfig.MTLSClientConfig(source, source, authorizer)
+}
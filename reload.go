@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frame
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sujit-baniya/frame/pkg/common/config"
+	"github.com/sujit-baniya/frame/pkg/route"
+	"github.com/sujit-baniya/frame/server/registry"
+)
+
+// ConfigProvider supplies the configuration re-read on each reload.
+type ConfigProvider interface {
+	// TLSConfig returns the TLS config to install, or nil to leave the
+	// current one in place.
+	TLSConfig() *tls.Config
+	// Routes registers routes on a freshly built route tree. Handlers
+	// registered here replace the previous tree wholesale.
+	Routes(rg *route.RouterGroup)
+	// Registry returns the registry and service info to install, or a nil
+	// registry.Registry to leave the current one in place.
+	Registry() (registry.Registry, *registry.Info)
+}
+
+// ReloadSpec pairs a ConfigProvider with the reload it drives.
+type ReloadSpec struct {
+	Provider ConfigProvider
+}
+
+// WithReloadOnSignal arranges for f.Reload(spec) to run whenever the process
+// receives one of sig (SIGHUP if none given). The handler is installed by
+// Frame.Spin, which invokes o.OnReload before it starts serving and runs the
+// returned cleanup after it stops, so the signal handler's lifetime matches
+// the running Frame's. See Reload for exactly what is and isn't swapped
+// without dropping the listener.
+func WithReloadOnSignal(spec ReloadSpec, sig ...os.Signal) config.Option {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	return config.Option{F: func(o *config.Options) {
+		o.OnReload = func(f *Frame) func() {
+			ch := make(chan os.Signal, 1)
+			signal.Notify(ch, sig...)
+			go func() {
+				for range ch {
+					if err := f.Reload(spec); err != nil {
+						log.Printf("frame: reload failed: %v", err)
+					}
+				}
+			}()
+			return func() {
+				signal.Stop(ch)
+				close(ch)
+			}
+		}
+	}}
+}
+
+// currentEngine returns the engine that should serve the next request,
+// guarded by the same mutex Reload swaps it under.
+func (f *Frame) currentEngine() *route.Engine {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.engine
+}
+
+// ServeHTTP dispatches through whichever engine Reload last swapped in. Spin
+// hands f.ServeHTTP (rather than the embedded *route.Engine's own ServeHTTP)
+// to the running transport, so a route reload takes effect for the next
+// request accepted on the already-open listener instead of requiring a new
+// one.
+func (f *Frame) ServeHTTP(c context.Context, ctx *Context) {
+	f.currentEngine().ServeHTTP(c, ctx)
+}
+
+// Reload re-reads spec.Provider and swaps the route tree, TLS config, and
+// registry info, without opening a second listener:
+//
+//   - Routes are registered on a freshly built *route.Engine, then f.engine is
+//     replaced under f.mu's write lock; the last Reload to take the lock
+//     wins. New requests reach it through f.ServeHTTP; requests already in
+//     flight keep running against the engine they started on. Routes
+//     registered by spec.Provider.Routes go straight onto the new engine's
+//     RouterGroup, so neither route filters nor observability wrapping from
+//     Frame.Handle are applied to them.
+//   - TLS is updated through o.TLSReload when the server was started with
+//     server.WithTLSStore. Otherwise the new config is only recorded on the
+//     new engine's options, and the listener Spin opened keeps serving the
+//     TLS config it started with.
+//   - The registry is re-registered against the new info directly, since the
+//     new engine is never itself passed to Spin.
+func (f *Frame) Reload(spec ReloadSpec) error {
+	if spec.Provider == nil {
+		return fmt.Errorf("frame: Reload requires a ConfigProvider")
+	}
+
+	oldOpts := f.currentEngine().Options()
+
+	newEngine := route.NewEngine(oldOpts)
+	spec.Provider.Routes(newEngine.RouterGroup)
+
+	if tlsCfg := spec.Provider.TLSConfig(); tlsCfg != nil {
+		if oldOpts.TLSReload != nil {
+			oldOpts.TLSReload(tlsCfg)
+		}
+		newEngine.Options().TLS = tlsCfg
+	}
+
+	reg, info := spec.Provider.Registry()
+	if reg != nil {
+		if err := reg.Register(info); err != nil {
+			return fmt.Errorf("frame: re-registering service: %w", err)
+		}
+		newEngine.Options().Registry = reg
+		newEngine.Options().RegistryInfo = info
+	}
+
+	f.mu.Lock()
+	f.engine = newEngine
+	f.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frame
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sujit-baniya/frame/middlewares/server/observability"
+	"github.com/sujit-baniya/frame/pkg/common/config"
+)
+
+// RouteMeta carries per-route metadata consulted by server.RouteFilter at
+// registration time.
+type RouteMeta struct {
+	Labels map[string]string
+}
+
+// RouteOption customizes a single route's metadata on an engine.Handle call,
+// e.g. frame.WithRouteLabels.
+type RouteOption func(*RouteMeta)
+
+// WithRouteLabels attaches labels to a route's metadata, so a
+// server.RouteFilter can match on structured labels (tag, surface, ...)
+// instead of string-matching the path or method.
+func WithRouteLabels(labels map[string]string) RouteOption {
+	return func(m *RouteMeta) {
+		if m.Labels == nil {
+			m.Labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			m.Labels[k] = v
+		}
+	}
+}
+
+// Handle registers handler for method and path, applying opts to build the
+// route's RouteMeta. It is the single chokepoint every registration helper on
+// Frame (GET, POST, ...) funnels through: every server.RouteFilter installed
+// via server.WithRouteFilters runs against the resulting config.RouteInfo
+// (Labels included) before the registration is forwarded to the engine, so a
+// rejected route never reaches route.Engine and is never counted in metrics
+// or engine.Routes(). If an observability.Manager was installed via
+// server.WithObservability, its Participation decision for path is also made
+// here, at registration time, and wraps handler so tracing and access logging
+// are a per-route, not a global, decision; metrics are recorded for every
+// wrapped route whenever the manager has a metrics registry.
+func (f *Frame) Handle(method, path string, handler HandlerFunc, opts ...RouteOption) {
+	meta := RouteMeta{}
+	for _, opt := range opts {
+		opt(&meta)
+	}
+
+	info := config.RouteInfo{Method: method, Path: path, Labels: meta.Labels}
+	for _, filter := range f.engine.Options().RouteFilters {
+		if !filter(info) {
+			return
+		}
+	}
+
+	if mgr := f.engine.Options().ObservabilityManager; mgr != nil {
+		handler = observe(mgr, method, path, handler)
+	}
+
+	f.engine.Handle(method, path, handler)
+}
+
+// GET registers handler for a GET request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) GET(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("GET", path, handler, opts...)
+}
+
+// POST registers handler for a POST request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) POST(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("POST", path, handler, opts...)
+}
+
+// PUT registers handler for a PUT request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) PUT(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("PUT", path, handler, opts...)
+}
+
+// DELETE registers handler for a DELETE request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) DELETE(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("DELETE", path, handler, opts...)
+}
+
+// PATCH registers handler for a PATCH request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) PATCH(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("PATCH", path, handler, opts...)
+}
+
+// HEAD registers handler for a HEAD request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) HEAD(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("HEAD", path, handler, opts...)
+}
+
+// OPTIONS registers handler for an OPTIONS request to path, through the same
+// filtering and observability as Handle.
+func (f *Frame) OPTIONS(path string, handler HandlerFunc, opts ...RouteOption) {
+	f.Handle("OPTIONS", path, handler, opts...)
+}
+
+// observe wraps next so the route's Participation (decided once here, at
+// registration time) gates whether the request is traced and written to the
+// access log; metrics are recorded whenever mgr.Metrics is set. Tracing is invoked only here, not also
+// through whatever unconditional per-request tracer mechanism o.Tracers may
+// drive elsewhere, so a route registered through Frame's Handle starts and
+// finishes each span exactly once regardless of which option wired the
+// tracer into mgr.
+func observe(mgr *observability.Manager, method, path string, next HandlerFunc) HandlerFunc {
+	participation := mgr.Participates(path)
+	if !participation.AccessLog && !participation.Tracing && mgr.Metrics == nil {
+		return next
+	}
+
+	return func(c context.Context, ctx *Context) {
+		if participation.Tracing {
+			for _, t := range mgr.Tracers {
+				c = t.Start(c, ctx)
+			}
+		}
+
+		start := time.Now()
+		next(c, ctx)
+		duration := time.Since(start)
+		statusCode := ctx.Response.StatusCode()
+
+		if mgr.Metrics != nil {
+			mgr.Metrics.ObserveRequest(c, method, path, statusCode, duration)
+		}
+		if participation.AccessLog {
+			mgr.LogAccess(fmt.Sprintf("%s %s %s %d %s\n", time.Now().Format(time.RFC3339), method, path, statusCode, duration))
+		}
+		if participation.Tracing {
+			for _, t := range mgr.Tracers {
+				t.Finish(c, ctx)
+			}
+		}
+	}
+}
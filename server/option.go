@@ -18,10 +18,14 @@ package server
 
 import (
 	"crypto/tls"
+	"github.com/sujit-baniya/frame/middlewares/server/spiffe"
 	"github.com/sujit-baniya/frame/server/registry"
 	"net"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/sujit-baniya/frame/middlewares/server/observability"
 	"github.com/sujit-baniya/frame/pkg/common/config"
 	"github.com/sujit-baniya/frame/pkg/common/tracer"
 	"github.com/sujit-baniya/frame/pkg/common/tracer/stats"
@@ -221,6 +225,18 @@ func WithTLS(cfg *tls.Config) config.Option {
 	}}
 }
 
+// WithSPIFFE sets the TLS config from a SPIFFE Workload API X.509 source.
+//
+// Certificates rotate automatically as SPIRE rotates the workload's SVID, and
+// peer SPIFFE IDs presented during the handshake are checked against
+// authorizer, e.g. tlsconfig.AuthorizeMemberOf(trustDomain).
+func WithSPIFFE(source *workloadapi.X509Source, authorizer tlsconfig.Authorizer) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		route.SetTransporter(standard.NewTransporter)
+		o.TLS = spiffe.ServerTLSConfig(source, authorizer)
+	}}
+}
+
 // WithListenConfig sets listener config.
 func WithListenConfig(l *net.ListenConfig) config.Option {
 	return config.Option{F: func(o *config.Options) {
@@ -256,10 +272,37 @@ func WithALPN(enable bool) config.Option {
 	}}
 }
 
+// WithObservability wires mgr, an observability.Manager holding the server's
+// metrics registry, access-log handler, and tracer(s). mgr.Shutdown is
+// appended to o.ShutdownHooks, which frame.Shutdown runs so the registry,
+// tracers, and access log are flushed and closed together instead of being
+// torn down independently.
+//
+// mgr.Tracers are not also appended to o.Tracers: a route registered through
+// Frame.Handle already starts and finishes them per mgr's Participation
+// decision, and duplicating them into o.Tracers would start and finish every
+// span a second time.
+func WithObservability(mgr *observability.Manager) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.ObservabilityManager = mgr
+		o.ShutdownHooks = append(o.ShutdownHooks, mgr.Shutdown)
+	}}
+}
+
 // WithTracer adds tracer to server.
+//
+// Deprecated: prefer WithObservability, which coordinates the tracer's
+// shutdown with the rest of the observability stack. WithTracer is kept as a
+// thin shim that appends to the server's observability.Manager; like
+// WithObservability, it leaves o.Tracers alone so Frame.Handle's per-route
+// Participation decision is the only thing that starts and finishes tracer.
 func WithTracer(t tracer.Tracer) config.Option {
 	return config.Option{F: func(o *config.Options) {
-		o.Tracers = append(o.Tracers, t)
+		if o.ObservabilityManager == nil {
+			o.ObservabilityManager = observability.NewManager(nil, nil, nil)
+			o.ShutdownHooks = append(o.ShutdownHooks, o.ObservabilityManager.Shutdown)
+		}
+		o.ObservabilityManager.AddTracer(t, nil)
 	}}
 }
 
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"github.com/sujit-baniya/frame/pkg/common/config"
+)
+
+// RouteInfo describes a single handler registration, evaluated against every
+// RouteFilter before the route reaches route.Engine.
+type RouteInfo struct {
+	Method string
+	Path   string
+	// Labels is set via frame.WithRouteLabels on the corresponding
+	// engine.Handle call, so filters can match structured metadata instead
+	// of the path or method string.
+	Labels map[string]string
+}
+
+// RouteFilter decides whether a registered handler should be kept. It runs
+// once per handler registered through frame.Frame's Handle (or GET, POST,
+// ...), before route.Engine ever sees the route, so such a rejected route
+// never appears in engine.Routes() and is never counted in metrics. Routes
+// registered on a route.RouterGroup directly, including those a
+// frame.ConfigProvider registers during frame.Reload, are not filtered.
+type RouteFilter func(RouteInfo) bool
+
+// WithRouteFilters installs filters that gate route registration, e.g. so the
+// same binary can serve an admin surface and a public surface, selected by
+// env, from a single set of registered handlers. A route is registered only
+// if every filter returns true for it.
+func WithRouteFilters(filters ...RouteFilter) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.RouteFilters = append(o.RouteFilters, func(info config.RouteInfo) bool {
+			ri := RouteInfo{Method: info.Method, Path: info.Path, Labels: info.Labels}
+			for _, f := range filters {
+				if !f(ri) {
+					return false
+				}
+			}
+			return true
+		})
+	}}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/sujit-baniya/frame/pkg/common/config"
+)
+
+func TestWithRouteFiltersRejectsWhenAnyFilterFails(t *testing.T) {
+	var seen []RouteInfo
+	always := func(info RouteInfo) bool {
+		seen = append(seen, info)
+		return true
+	}
+	never := func(info RouteInfo) bool { return false }
+
+	opt := WithRouteFilters(always, never)
+	o := &config.Options{}
+	opt.F(o)
+
+	if len(o.RouteFilters) != 1 {
+		t.Fatalf("expected exactly one combined filter to be installed, got %d", len(o.RouteFilters))
+	}
+
+	if o.RouteFilters[0](config.RouteInfo{Method: "GET", Path: "/admin"}) {
+		t.Fatalf("expected the route to be rejected because one of the filters returned false")
+	}
+	if len(seen) != 1 {
+		t.Fatalf("expected the first filter to have run before the rejecting one, got %d calls", len(seen))
+	}
+}
+
+func TestWithRouteFiltersAcceptsWhenAllPass(t *testing.T) {
+	byMethod := func(info RouteInfo) bool { return info.Method == "GET" }
+	byLabel := func(info RouteInfo) bool { return info.Labels["surface"] == "public" }
+
+	opt := WithRouteFilters(byMethod, byLabel)
+	o := &config.Options{}
+	opt.F(o)
+
+	info := config.RouteInfo{Method: "GET", Path: "/health", Labels: map[string]string{"surface": "public"}}
+	if !o.RouteFilters[0](info) {
+		t.Fatalf("expected the route to be accepted when every filter passes")
+	}
+}
+
+func TestWithRouteFiltersComposesAcrossMultipleCalls(t *testing.T) {
+	o := &config.Options{}
+	WithRouteFilters(func(RouteInfo) bool { return true }).F(o)
+	WithRouteFilters(func(RouteInfo) bool { return false }).F(o)
+
+	if len(o.RouteFilters) != 2 {
+		t.Fatalf("expected two independently installed filters, got %d", len(o.RouteFilters))
+	}
+	if o.RouteFilters[0](config.RouteInfo{Method: "GET", Path: "/"}) == false {
+		t.Fatalf("expected the first installed filter group to accept")
+	}
+	if o.RouteFilters[1](config.RouteInfo{Method: "GET", Path: "/"}) {
+		t.Fatalf("expected the second installed filter group to reject")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/sujit-baniya/frame/pkg/common/config"
+	"github.com/sujit-baniya/frame/pkg/network/standard"
+	"github.com/sujit-baniya/frame/pkg/route"
+)
+
+// TLSOptions is a single named TLS configuration selectable by SNI hostname.
+type TLSOptions struct {
+	// ServerName is the SNI hostname this configuration is served for.
+	ServerName string
+	// Config is the TLS config served for ServerName.
+	Config *tls.Config
+}
+
+// TLSStore holds multiple named TLS configurations keyed by SNI hostname,
+// plus a default and a degraded fallback certificate served when an entry
+// fails to load or validate. A store can be swapped at runtime, e.g. from a
+// config reload handler, without dropping the listener.
+type TLSStore struct {
+	mu       sync.RWMutex
+	def      *tls.Config
+	fallback *tls.Config
+	byName   map[string]*tls.Config
+}
+
+// NewTLSStore builds a TLSStore from def (served when the ClientHello carries
+// no SNI or an unrecognized one), fallback (served in place of any entry in
+// opts that fails to validate), and opts (the per-SNI configurations).
+func NewTLSStore(def, fallback *tls.Config, opts ...TLSOptions) *TLSStore {
+	s := &TLSStore{def: def, fallback: fallback}
+	s.Swap(def, opts...)
+	return s
+}
+
+// Swap atomically replaces the store's default and, if opts is non-empty,
+// its per-SNI entries. Entries that fail to validate are logged and served
+// the fallback certificate instead of causing the handshake to fail.
+//
+// Swap with no opts leaves the existing per-SNI entries untouched and only
+// updates the default certificate, so a reload path that only knows about
+// the default (e.g. frame.Reload's TLSReload call) can't silently wipe every
+// per-SNI entry the store was built with. Pass the full set of TLSOptions
+// again to actually replace them.
+func (s *TLSStore) Swap(def *tls.Config, opts ...TLSOptions) {
+	var byName map[string]*tls.Config
+	if len(opts) > 0 {
+		byName = make(map[string]*tls.Config, len(opts))
+		for _, opt := range opts {
+			cfg := opt.Config
+			if err := validateTLSConfig(cfg); err != nil {
+				log.Printf("frame: TLS options for %q are invalid, serving fallback certificate: %v", opt.ServerName, err)
+				cfg = s.fallback
+			}
+			byName[opt.ServerName] = cfg
+		}
+	}
+
+	s.mu.Lock()
+	if def != nil {
+		s.def = def
+	}
+	if byName != nil {
+		s.byName = byName
+	}
+	s.mu.Unlock()
+}
+
+// GetConfigForClient selects a *tls.Config per ClientHello based on SNI,
+// falling back to the store's default when no entry matches. It is meant to
+// be installed as (*tls.Config).GetConfigForClient.
+func (s *TLSStore) GetConfigForClient(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cfg, ok := s.byName[hello.ServerName]; ok {
+		return cfg, nil
+	}
+	return s.def, nil
+}
+
+func validateTLSConfig(cfg *tls.Config) error {
+	if cfg == nil {
+		return errors.New("nil tls config")
+	}
+	if len(cfg.Certificates) == 0 && cfg.GetCertificate == nil {
+		return errors.New("no certificate configured")
+	}
+	return nil
+}
+
+// WithTLSStore installs store as the server's per-SNI TLS selector, so each
+// ClientHello can be served a different certificate. A misconfigured entry
+// logs a warning and serves the store's fallback certificate instead of
+// terminating the handshake. o.TLSReload is also set to a function that swaps
+// store's default in place, so a later reload (see frame.Reload) can update the
+// certificates served by the already-open listener without rebinding it.
+func WithTLSStore(store *TLSStore) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		route.SetTransporter(standard.NewTransporter)
+
+		cfg := store.def.Clone()
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		cfg.GetConfigForClient = store.GetConfigForClient
+		o.TLS = cfg
+		o.TLSReload = func(def *tls.Config) {
+			store.Swap(def)
+		}
+	}}
+}
+
+// WithTLSOptions is a convenience wrapper around WithTLSStore for callers that
+// don't need to keep a reference to the store, e.g. because the per-SNI
+// configuration is static for the lifetime of the server. Use WithTLSStore
+// directly when the store needs to be swapped later, for example by a
+// SIGHUP reload handler.
+func WithTLSOptions(def, fallback *tls.Config, opts ...TLSOptions) config.Option {
+	return WithTLSStore(NewTLSStore(def, fallback, opts...))
+}
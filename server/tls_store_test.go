@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestTLSStoreGetConfigForClientSelectsBySNI(t *testing.T) {
+	def := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("default")}}}}
+	apiCfg := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("api")}}}}
+	fallback := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("fallback")}}}}
+
+	store := NewTLSStore(def, fallback, TLSOptions{ServerName: "api.example.com", Config: apiCfg})
+
+	got, err := store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "api.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != apiCfg {
+		t.Fatalf("expected the api.example.com config, got a different one")
+	}
+
+	got, err = store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "unknown.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != def {
+		t.Fatalf("expected the default config for an unrecognized SNI")
+	}
+}
+
+func TestTLSStoreInvalidEntryServesFallback(t *testing.T) {
+	def := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("default")}}}}
+	fallback := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("fallback")}}}}
+
+	store := NewTLSStore(def, fallback, TLSOptions{ServerName: "broken.example.com", Config: nil})
+
+	got, err := store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "broken.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != fallback {
+		t.Fatalf("expected the fallback config for an invalid entry")
+	}
+}
+
+func TestTLSStoreSwapReplacesEntries(t *testing.T) {
+	def := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("default")}}}}
+	v1 := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("v1")}}}}
+	v2 := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("v2")}}}}
+
+	store := NewTLSStore(def, nil, TLSOptions{ServerName: "api.example.com", Config: v1})
+	store.Swap(def, TLSOptions{ServerName: "api.example.com", Config: v2})
+
+	got, _ := store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "api.example.com"})
+	if got != v2 {
+		t.Fatalf("expected Swap to replace the per-SNI entry")
+	}
+}
+
+func TestTLSStoreSwapWithoutOptionsPreservesExistingEntries(t *testing.T) {
+	def := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("default")}}}}
+	newDef := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("new-default")}}}}
+	v1 := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("v1")}}}}
+
+	store := NewTLSStore(def, nil, TLSOptions{ServerName: "api.example.com", Config: v1})
+	store.Swap(newDef) // e.g. a reload path that only knows about the default cert
+
+	got, _ := store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "api.example.com"})
+	if got != v1 {
+		t.Fatalf("expected Swap with no opts to leave the api.example.com entry untouched")
+	}
+	got, _ = store.GetConfigForClient(&tls.ClientHelloInfo{ServerName: "unknown.example.com"})
+	if got != newDef {
+		t.Fatalf("expected the default config to still be updated")
+	}
+}